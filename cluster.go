@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+func (sync *Synchronizer) findCluster(name string) *Cluster {
+	for i := range sync.config.Clusters {
+		if sync.config.Clusters[i].Name == name {
+			return &sync.config.Clusters[i]
+		}
+	}
+
+	return nil
+}
+
+func (sync *Synchronizer) serverIndex(server Server) (int, bool) {
+	for i, s := range sync.config.Servers {
+		if s.Host == server.Host && s.Port == server.Port {
+			return i, true
+		}
+	}
+
+	return 0, false
+}
+
+// representativeHosts returns, for each shard of the named cluster, the
+// index into sync.connections of its first configured replica. DDL only
+// needs to run against one host per shard: ON CLUSTER fans it out to every
+// replica.
+func (sync *Synchronizer) representativeHosts(clusterName string) []int {
+	cluster := sync.findCluster(clusterName)
+	if cluster == nil {
+		return nil
+	}
+
+	var hosts []int
+	for _, shard := range cluster.Shards {
+		if len(shard) == 0 {
+			continue
+		}
+
+		if i, ok := sync.serverIndex(shard[0]); ok {
+			hosts = append(hosts, i)
+		}
+	}
+
+	return hosts
+}
+
+// validateClusterTopology compares the shard/replica counts configured for
+// clusterName against what the server at connection i reports through
+// system.clusters, logging any mismatch.
+func (sync *Synchronizer) validateClusterTopology(i int, clusterName string) {
+	cluster := sync.findCluster(clusterName)
+	if cluster == nil {
+		return
+	}
+
+	l := log.WithFields(log.Fields{
+		"host":    sync.config.Servers[i].Host,
+		"cluster": clusterName,
+	})
+
+	rows, err := sync.connections[i].Query(
+		"SELECT shard_num, replica_num FROM system.clusters WHERE cluster = ?", clusterName,
+	)
+	if err != nil {
+		l.WithError(err).Error("Failed to read cluster topology")
+		return
+	}
+	defer rows.Close()
+
+	liveReplicas := make(map[int]int)
+	for rows.Next() {
+		var shardNum, replicaNum int
+		if err := rows.Scan(&shardNum, &replicaNum); err != nil {
+			l.WithError(err).Error("Failed to read cluster topology")
+			return
+		}
+
+		if replicaNum > liveReplicas[shardNum] {
+			liveReplicas[shardNum] = replicaNum
+		}
+	}
+
+	if len(liveReplicas) != len(cluster.Shards) {
+		l.WithFields(log.Fields{
+			"config shards": len(cluster.Shards),
+			"live shards":   len(liveReplicas),
+		}).Error("Cluster shard count mismatch")
+	}
+
+	for shardIdx, shard := range cluster.Shards {
+		shardNum := shardIdx + 1
+		if liveReplicas[shardNum] != len(shard) {
+			l.WithFields(log.Fields{
+				"shard":           shardNum,
+				"config replicas": len(shard),
+				"live replicas":   liveReplicas[shardNum],
+			}).Error("Cluster replica count mismatch")
+		}
+	}
+}
+
+// checkDistributedTable ensures the Distributed wrapper for a sharded table
+// exists when table.Distributed is set, creating/reconciling it under the
+// "<name>_all" name by the usual CheckTable path.
+func (sync *Synchronizer) checkDistributedTable(name string, table Table) HostErrors {
+	if !table.Distributed || table.Cluster == "" {
+		return nil
+	}
+
+	engine := fmt.Sprintf("Distributed(%s, %s, %s", table.Cluster, sync.database, name)
+	if table.ShardingKey != "" {
+		engine += ", " + table.ShardingKey
+	}
+	engine += ")"
+
+	return sync.CheckTable(name+"_all", Table{
+		Engine:         engine,
+		AsAnotherTable: name,
+		Cluster:        table.Cluster,
+	})
+}
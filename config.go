@@ -2,6 +2,7 @@ package main
 
 import (
 	"io/ioutil"
+
 	"gopkg.in/yaml.v2"
 )
 
@@ -12,26 +13,77 @@ type Server struct {
 	Pass string `yaml:"pass"`
 }
 
-type Columns map[string]string
+// Column describes a single table column. It may be given in YAML either as
+// a bare type string (the original shorthand, e.g. `id: UInt64`) or as a
+// mapping carrying the extra MergeTree clauses chsync now understands.
+type Column struct {
+	Type        string `yaml:"type"`
+	Default     string `yaml:"default"`
+	DefaultKind string `yaml:"default_kind"`
+	Codec       string `yaml:"codec"`
+	TTL         string `yaml:"ttl"`
+	Comment     string `yaml:"comment"`
+}
+
+// UnmarshalYAML accepts either `name: Type` or the expanded
+// `name: {type: Type, default: ..., default_kind: ..., codec: ..., ttl: ..., comment: ...}` form.
+func (c *Column) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var shorthand string
+	if err := unmarshal(&shorthand); err == nil {
+		c.Type = shorthand
+		return nil
+	}
+
+	type plain Column
+	var full plain
+	if err := unmarshal(&full); err != nil {
+		return err
+	}
+
+	*c = Column(full)
+	return nil
+}
+
+type Columns map[string]Column
 
 type Table struct {
-	View           bool `yaml:"view"`
-	Materialized   bool `yaml:"materialized"`
-	Populate       bool `yaml:"populate"`
-	Columns        Columns `yaml:"columns"`
-	Engine         string `yaml:"engine"`
-	AsAnotherTable string `yaml:"as_table"`
-	AsSelect       string `yaml:"as_select"`
+	View           bool              `yaml:"view"`
+	Materialized   bool              `yaml:"materialized"`
+	Populate       bool              `yaml:"populate"`
+	Columns        Columns           `yaml:"columns"`
+	Engine         string            `yaml:"engine"`
+	AsAnotherTable string            `yaml:"as_table"`
+	AsSelect       string            `yaml:"as_select"`
+	OrderBy        []string          `yaml:"order_by"`
+	PartitionBy    string            `yaml:"partition_by"`
+	PrimaryKey     []string          `yaml:"primary_key"`
+	SampleBy       string            `yaml:"sample_by"`
+	TTL            string            `yaml:"ttl"`
+	Settings       map[string]string `yaml:"settings"`
+	Cluster        string            `yaml:"cluster"`
+	Distributed    bool              `yaml:"distributed"`
+	ShardingKey    string            `yaml:"sharding_key"`
 }
 
 type Database struct {
-	Name   string `yaml:"name"`
-	Tables map[string]Table `yaml:"tables"`
+	Name    string           `yaml:"name"`
+	Tables  map[string]Table `yaml:"tables"`
+	Cluster string           `yaml:"cluster"`
+}
+
+// Cluster describes a ClickHouse cluster topology as seen from config: one
+// set of servers per shard, replicas within a shard listed in order. It
+// mirrors the shape reported by system.clusters.
+type Cluster struct {
+	Name   string     `yaml:"name"`
+	Shards [][]Server `yaml:"shards"`
 }
 
 type Config struct {
-	Servers   []Server `yaml:"servers"`
-	Databases []Database `yaml:"databases"`
+	Servers     []Server   `yaml:"servers"`
+	Databases   []Database `yaml:"databases"`
+	Clusters    []Cluster  `yaml:"clusters"`
+	Parallelism int        `yaml:"parallelism"`
 }
 
 func ParseConfig(path string) *Config {
@@ -47,4 +99,4 @@ func ParseConfig(path string) *Config {
 	}
 
 	return config
-}
\ No newline at end of file
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// columnSQL renders a single column definition, including its
+// DEFAULT/MATERIALIZED/ALIAS, CODEC and COMMENT clauses when present.
+func columnSQL(name string, column Column) string {
+	sql := name + " " + column.Type
+
+	if column.Default != "" {
+		kind := column.DefaultKind
+		if kind == "" {
+			kind = "DEFAULT"
+		}
+		sql += " " + kind + " " + column.Default
+	}
+
+	if column.Codec != "" {
+		sql += " CODEC(" + column.Codec + ")"
+	}
+
+	if column.TTL != "" {
+		sql += " TTL " + column.TTL
+	}
+
+	if column.Comment != "" {
+		sql += " COMMENT '" + escapeSQLString(column.Comment) + "'"
+	}
+
+	return sql
+}
+
+func escapeSQLString(s string) string {
+	return strings.Replace(s, "'", "''", -1)
+}
+
+// onClusterSQL renders the `ON CLUSTER name` clause for a DDL statement, or
+// the empty string when the table is not cluster-aware.
+func onClusterSQL(cluster string) string {
+	if cluster == "" {
+		return ""
+	}
+
+	return " ON CLUSTER " + cluster
+}
+
+func columnsSQL(columns Columns) string {
+	if len(columns) == 0 {
+		return ""
+	}
+
+	defs := make([]string, 0, len(columns))
+	for name, column := range columns {
+		defs = append(defs, columnSQL(name, column))
+	}
+	sort.Strings(defs)
+
+	return "(" + strings.Join(defs, ", ") + ") "
+}
+
+// tableClausesSQL renders the MergeTree-family clauses (ORDER BY,
+// PARTITION BY, PRIMARY KEY, SAMPLE BY, TTL, SETTINGS) that follow ENGINE in
+// a CREATE TABLE statement.
+func tableClausesSQL(table Table) string {
+	var clauses []string
+
+	if len(table.OrderBy) != 0 {
+		clauses = append(clauses, "ORDER BY ("+strings.Join(table.OrderBy, ", ")+")")
+	}
+
+	if table.PartitionBy != "" {
+		clauses = append(clauses, "PARTITION BY "+table.PartitionBy)
+	}
+
+	if len(table.PrimaryKey) != 0 {
+		clauses = append(clauses, "PRIMARY KEY ("+strings.Join(table.PrimaryKey, ", ")+")")
+	}
+
+	if table.SampleBy != "" {
+		clauses = append(clauses, "SAMPLE BY "+table.SampleBy)
+	}
+
+	if table.TTL != "" {
+		clauses = append(clauses, "TTL "+table.TTL)
+	}
+
+	if len(table.Settings) != 0 {
+		settings := make([]string, 0, len(table.Settings))
+		for name, value := range table.Settings {
+			settings = append(settings, name+" = "+value)
+		}
+		sort.Strings(settings)
+		clauses = append(clauses, "SETTINGS "+strings.Join(settings, ", "))
+	}
+
+	if len(clauses) == 0 {
+		return ""
+	}
+
+	return " " + strings.Join(clauses, " ")
+}
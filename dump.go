@@ -0,0 +1,201 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Dump reverse-engineers a Config for database from the first connected
+// server, reading table and column definitions out of system.tables and
+// system.columns. It is the inverse of CheckTable: instead of reconciling a
+// config against a live schema, it produces the config.
+func (sync *Synchronizer) Dump(database string) (*Config, error) {
+	if len(sync.connections) == 0 {
+		return nil, errors.New("no connection available to dump from")
+	}
+
+	tables, err := dumpTables(sync.connections[0], database)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		Databases: []Database{
+			{Name: database, Tables: tables},
+		},
+	}, nil
+}
+
+func dumpTables(conn *sql.DB, database string) (map[string]Table, error) {
+	rows, err := conn.Query(
+		`SELECT name, engine, engine_full, create_table_query, sorting_key, partition_key, primary_key, sampling_key
+		 FROM system.tables WHERE database = ?`,
+		database,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tables := make(map[string]Table)
+
+	for rows.Next() {
+		var name, engine, engineFull, createTableQuery, sortingKey, partitionKey, primaryKey, samplingKey string
+
+		if err := rows.Scan(&name, &engine, &engineFull, &createTableQuery, &sortingKey, &partitionKey, &primaryKey, &samplingKey); err != nil {
+			return nil, err
+		}
+
+		isView := engine == "View" || engine == "MaterializedView"
+
+		table := Table{}
+		if !isView {
+			table.Engine = parseEngine(engineFull)
+		}
+
+		if sortingKey != "" {
+			table.OrderBy = strings.Split(sortingKey, ", ")
+		}
+		table.PartitionBy = partitionKey
+		if primaryKey != "" {
+			table.PrimaryKey = strings.Split(primaryKey, ", ")
+		}
+		table.SampleBy = samplingKey
+
+		if isView {
+			table.View = true
+			table.Materialized = engine == "MaterializedView"
+			table.AsSelect = parseAsSelect(createTableQuery)
+		}
+
+		columns, err := dumpColumns(conn, database, name)
+		if err != nil {
+			return nil, err
+		}
+		table.Columns = columns
+
+		tables[name] = table
+	}
+
+	return tables, rows.Err()
+}
+
+// parseAsSelect extracts the SELECT query a [MATERIALIZED] VIEW was created
+// with out of its create_table_query, matching "AS SELECT" case-insensitively
+// since ClickHouse doesn't normalize keyword case in the stored DDL.
+func parseAsSelect(createTableQuery string) string {
+	idx := strings.Index(strings.ToUpper(createTableQuery), " AS SELECT ")
+	if idx == -1 {
+		return ""
+	}
+
+	return strings.TrimSpace(createTableQuery[idx+len(" AS "):])
+}
+
+func dumpColumns(conn *sql.DB, database, table string) (Columns, error) {
+	rows, err := conn.Query(
+		"SELECT name, type, default_kind, default_expression, comment FROM system.columns WHERE database = ? AND table = ?",
+		database, table,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(Columns)
+
+	for rows.Next() {
+		var name, columnType, defaultKind, defaultExpr, comment string
+
+		if err := rows.Scan(&name, &columnType, &defaultKind, &defaultExpr, &comment); err != nil {
+			return nil, err
+		}
+
+		column := Column{
+			Type:    columnType,
+			Default: defaultExpr,
+			Comment: comment,
+		}
+
+		// MATERIALIZED/ALIAS columns are computed, not stored defaults;
+		// carry the kind through so columnSQL emits the right keyword
+		// instead of silently turning them into plain DEFAULTs.
+		if defaultKind != "" && defaultKind != "DEFAULT" {
+			column.DefaultKind = defaultKind
+		}
+
+		columns[name] = column
+	}
+
+	return columns, rows.Err()
+}
+
+// parseEngine strips the trailing ORDER BY/PARTITION BY/... clauses system
+// tables.engine_full appends, leaving just the bare "Engine(args)" chsync
+// expects in the `engine:` field. ORDER BY, PARTITION BY, PRIMARY KEY and
+// SAMPLE BY are dumped into their own Table fields from system.tables; the
+// TTL and SETTINGS clauses are dropped and do not appear in the dump.
+func parseEngine(engineFull string) string {
+	for _, clause := range []string{" ORDER BY", " PARTITION BY", " PRIMARY KEY", " SAMPLE BY", " TTL", " SETTINGS"} {
+		if idx := strings.Index(engineFull, clause); idx != -1 {
+			engineFull = engineFull[:idx]
+		}
+	}
+
+	return strings.TrimSpace(engineFull)
+}
+
+// runDump implements the `chsync dump` subcommand: connect to a single live
+// server and print a config.yml that reconstructs its schema.
+func runDump(args []string) {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+
+	host := flags.String("host", "localhost", "server host")
+	port := flags.Uint("port", 9000, "server port")
+	user := flags.String("user", "default", "server user")
+	pass := flags.String("pass", "", "server password")
+	database := flags.String("database", "default", "database to dump")
+	output := flags.String("output", "", "write config to this path instead of stdout")
+
+	flags.Parse(args)
+
+	config := &Config{
+		Servers: []Server{
+			{Host: *host, Port: uint16(*port), User: *user, Pass: *pass},
+		},
+	}
+
+	synchronizer := NewSynchronizer(config)
+	if err := synchronizer.Connect(); err != nil {
+		panic(err)
+	}
+
+	dumped, err := synchronizer.Dump(*database)
+	if err != nil {
+		panic(err)
+	}
+
+	if err := synchronizer.Close(); err != nil {
+		panic(err)
+	}
+
+	data, err := yaml.Marshal(dumped)
+	if err != nil {
+		panic(err)
+	}
+
+	if *output == "" {
+		fmt.Print(string(data))
+		return
+	}
+
+	if err := ioutil.WriteFile(*output, data, 0644); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import "strings"
+
+// HostError associates a failure with the server and operation that
+// produced it, so callers can tell which of several concurrently-run
+// operations failed.
+type HostError struct {
+	Host string
+	Op   string
+	Err  error
+}
+
+func (e *HostError) Error() string {
+	return e.Host + ": " + e.Op + ": " + e.Err.Error()
+}
+
+// HostErrors aggregates HostError values collected while fanning work out
+// across servers.
+type HostErrors []*HostError
+
+func (e HostErrors) Error() string {
+	texts := make([]string, len(e))
+	for i, he := range e {
+		texts[i] = he.Error()
+	}
+
+	return strings.Join(texts, "\n")
+}
+
+// HasErrors reports whether any host failed.
+func (e HostErrors) HasErrors() bool {
+	return len(e) > 0
+}
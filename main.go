@@ -1,25 +1,46 @@
 package main
 
-import "flag"
+import (
+	"flag"
+	"fmt"
+	"os"
+)
 
 var sync bool
 var config string
 var debug, dropColumns bool
+var migrate bool
+var rollback int
+var plan bool
+var planFormat string
+var parallelism int
 
 func init() {
 	flag.StringVar(&config, "config", "config.yml", "config path")
 	flag.BoolVar(&sync, "sync", false, "sync schema with config")
 	flag.BoolVar(&dropColumns, "drop-columns", false, "drop excess columns")
 	flag.BoolVar(&debug, "debug", false, "debug output")
+	flag.BoolVar(&migrate, "migrate", false, "run pending schema migrations")
+	flag.IntVar(&rollback, "rollback", 0, "roll back the last N applied migrations")
+	flag.BoolVar(&plan, "plan", false, "print the DDL that would run without executing it")
+	flag.StringVar(&planFormat, "plan-format", "text", "plan output format: text or json")
+	flag.IntVar(&parallelism, "parallelism", 0, "max number of servers to operate on concurrently (0: config value, falling back to one per server)")
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "dump" {
+		runDump(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 	config := ParseConfig(config)
 
 	synchronizer := NewSynchronizer(config)
 	synchronizer.SetFix(sync)
 	synchronizer.SetDropColumns(dropColumns)
+	synchronizer.SetPlan(plan)
+	synchronizer.SetParallelism(parallelism)
 
 	synchronizer.SetupLogger(debug)
 	err := synchronizer.Connect()
@@ -27,10 +48,40 @@ func main() {
 		panic(err)
 	}
 
-	synchronizer.Check()
+	var errs HostErrors
+	switch {
+	case rollback > 0:
+		synchronizer.Rollback(rollback)
+	case migrate:
+		synchronizer.Migrate()
+	default:
+		errs = synchronizer.Check()
+	}
+
+	if plan {
+		printPlan(synchronizer.Plan())
+	}
 
 	err = synchronizer.Close()
 	if err != nil {
 		panic(err)
 	}
+
+	if errs.HasErrors() {
+		fmt.Fprintln(os.Stderr, errs.Error())
+		os.Exit(1)
+	}
+}
+
+func printPlan(p *Plan) {
+	if planFormat == "json" {
+		data, err := p.JSON()
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	fmt.Print(p.String())
 }
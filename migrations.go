@@ -0,0 +1,179 @@
+package main
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Migration describes a single schema migration step, identified by a
+// timestamp-like ID (e.g. 20240115120000). Up applies the migration, Down
+// reverts it; both receive the per-server connection and a logger scoped to
+// the migration so implementations can report progress.
+type Migration struct {
+	ID          string
+	Description string
+	Up          func(db *sql.DB, l *log.Entry) error
+	Down        func(db *sql.DB, l *log.Entry) error
+}
+
+var registeredMigrations []Migration
+
+// RegisterMigration adds a migration to the global registry. Call it from an
+// init() function in the file defining the migration so new migrations can
+// be dropped in without touching existing code.
+func RegisterMigration(m Migration) {
+	registeredMigrations = append(registeredMigrations, m)
+}
+
+func sortedMigrations() []Migration {
+	migrations := make([]Migration, len(registeredMigrations))
+	copy(migrations, registeredMigrations)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ID < migrations[j].ID
+	})
+
+	return migrations
+}
+
+// schema_migrations must live on a MergeTree-family engine: Rollback removes
+// applied records with ALTER TABLE ... DELETE, a mutation Log-family engines
+// (TinyLog/Log/StripeLog) reject outright.
+const createMigrationsTableSQL = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	id String,
+	description String,
+	applied_at DateTime
+) ENGINE = ReplacingMergeTree(applied_at)
+ORDER BY id`
+
+func (sync *Synchronizer) ensureMigrationsTable(i int) error {
+	_, err := sync.connections[i].Exec(createMigrationsTableSQL)
+	return err
+}
+
+func (sync *Synchronizer) appliedMigrations(i int) (map[string]bool, error) {
+	rows, err := sync.connections[i].Query("SELECT id FROM schema_migrations FINAL")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		applied[id] = true
+	}
+
+	return applied, rows.Err()
+}
+
+func (sync *Synchronizer) recordMigration(i int, m Migration) error {
+	_, err := sync.connections[i].Exec(
+		"INSERT INTO schema_migrations (id, description, applied_at) VALUES (?, ?, ?)",
+		m.ID, m.Description, time.Now(),
+	)
+	return err
+}
+
+func (sync *Synchronizer) unrecordMigration(i int, m Migration) error {
+	_, err := sync.connections[i].Exec("ALTER TABLE schema_migrations DELETE WHERE id = ?", m.ID)
+	return err
+}
+
+// Migrate applies every registered migration not yet recorded as applied on
+// each server, in ID order.
+func (sync *Synchronizer) Migrate() {
+	migrations := sortedMigrations()
+
+	for i, server := range sync.config.Servers {
+		l := log.WithField("host", server.Host)
+
+		if err := sync.ensureMigrationsTable(i); err != nil {
+			l.WithError(err).Error("Failed to create schema_migrations table")
+			continue
+		}
+
+		applied, err := sync.appliedMigrations(i)
+		if err != nil {
+			l.WithError(err).Error("Failed to read applied migrations")
+			continue
+		}
+
+		for _, m := range migrations {
+			if applied[m.ID] {
+				continue
+			}
+
+			ml := l.WithFields(log.Fields{"id": m.ID, "description": m.Description})
+
+			if err := m.Up(sync.connections[i], ml); err != nil {
+				ml.WithError(err).Error("Migration failed")
+				break
+			}
+
+			if err := sync.recordMigration(i, m); err != nil {
+				ml.WithError(err).Error("Failed to record migration")
+				break
+			}
+
+			ml.Info("Applied migration")
+		}
+	}
+}
+
+// Rollback invokes Down for the last n applied migrations on each server, in
+// reverse ID order.
+func (sync *Synchronizer) Rollback(n int) {
+	byID := make(map[string]Migration)
+	for _, m := range sortedMigrations() {
+		byID[m.ID] = m
+	}
+
+	for i, server := range sync.config.Servers {
+		l := log.WithField("host", server.Host)
+
+		applied, err := sync.appliedMigrations(i)
+		if err != nil {
+			l.WithError(err).Error("Failed to read applied migrations")
+			continue
+		}
+
+		var ids []string
+		for id := range applied {
+			ids = append(ids, id)
+		}
+		sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+		if len(ids) > n {
+			ids = ids[:n]
+		}
+
+		for _, id := range ids {
+			m, ok := byID[id]
+			if !ok {
+				l.WithField("id", id).Warn("Applied migration is not registered, skipping rollback")
+				continue
+			}
+
+			ml := l.WithFields(log.Fields{"id": m.ID, "description": m.Description})
+
+			if err := m.Down(sync.connections[i], ml); err != nil {
+				ml.WithError(err).Error("Rollback failed")
+				continue
+			}
+
+			if err := sync.unrecordMigration(i, m); err != nil {
+				ml.WithError(err).Error("Failed to unrecord migration")
+				continue
+			}
+
+			ml.Info("Rolled back migration")
+		}
+	}
+}
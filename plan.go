@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	syncpkg "sync"
+)
+
+// PlanStatement is a single DDL statement that would be executed against a
+// server, produced instead of running it when the synchronizer is in plan
+// mode.
+type PlanStatement struct {
+	Host     string `json:"host"`
+	Database string `json:"database"`
+	Table    string `json:"table"`
+	Reason   string `json:"reason"`
+	SQL      string `json:"sql"`
+}
+
+// Plan collects the DDL statements CheckTable would otherwise have executed,
+// without mutating any server.
+type Plan struct {
+	mu         syncpkg.Mutex
+	Statements []PlanStatement
+}
+
+func (p *Plan) add(host, database, table, reason, sql string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Statements = append(p.Statements, PlanStatement{
+		Host:     host,
+		Database: database,
+		Table:    table,
+		Reason:   reason,
+		SQL:      sql,
+	})
+}
+
+// JSON renders the plan as indented JSON for machine consumption.
+func (p *Plan) JSON() ([]byte, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return json.MarshalIndent(p.Statements, "", "  ")
+}
+
+// String renders the plan grouped by host for human review.
+func (p *Plan) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var hosts []string
+	byHost := make(map[string][]PlanStatement)
+	for _, s := range p.Statements {
+		if _, ok := byHost[s.Host]; !ok {
+			hosts = append(hosts, s.Host)
+		}
+		byHost[s.Host] = append(byHost[s.Host], s)
+	}
+
+	var b strings.Builder
+	for _, host := range hosts {
+		fmt.Fprintf(&b, "-- %s\n", host)
+		for _, s := range byHost[host] {
+			fmt.Fprintf(&b, "-- %s.%s: %s\n%s;\n\n", s.Database, s.Table, s.Reason, s.SQL)
+		}
+	}
+
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"database/sql"
+	syncpkg "sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// SetParallelism bounds how many servers chsync talks to at once. A value
+// <= 0 means "use config.Parallelism, falling back to one goroutine per
+// server" (see effectiveParallelism).
+func (sync *Synchronizer) SetParallelism(parallelism int) {
+	sync.parallelism = parallelism
+}
+
+func (sync *Synchronizer) effectiveParallelism() int {
+	if sync.parallelism > 0 {
+		return sync.parallelism
+	}
+
+	if sync.config.Parallelism > 0 {
+		return sync.config.Parallelism
+	}
+
+	return len(sync.connections)
+}
+
+// forEachConnection runs fn once per connection, bounded to
+// effectiveParallelism() concurrent calls, and blocks until all of them
+// return.
+func (sync *Synchronizer) forEachConnection(fn func(i int, conn *sql.DB)) {
+	sem := make(chan struct{}, sync.effectiveParallelism())
+	var g errgroup.Group
+
+	for i, conn := range sync.connections {
+		i, conn := i, conn
+
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+
+			fn(i, conn)
+			return nil
+		})
+	}
+
+	g.Wait()
+}
+
+// fixTask is a single reconciliation action (create, alter, drop, ...)
+// targeting one server.
+type fixTask struct {
+	host int
+	op   string
+	run  func() error
+}
+
+// runFixTasks executes every task, bounded to effectiveParallelism()
+// concurrent operations, waits for all of them to complete and aggregates
+// every failure into a HostErrors.
+func (sync *Synchronizer) runFixTasks(tasks []fixTask) HostErrors {
+	sem := make(chan struct{}, sync.effectiveParallelism())
+	var g errgroup.Group
+	var mu syncpkg.Mutex
+	var errs HostErrors
+
+	for _, t := range tasks {
+		t := t
+
+		sem <- struct{}{}
+		g.Go(func() error {
+			defer func() { <-sem }()
+
+			if err := t.run(); err != nil {
+				mu.Lock()
+				errs = append(errs, &HostError{
+					Host: sync.config.Servers[t.host].Host,
+					Op:   t.op,
+					Err:  err,
+				})
+				mu.Unlock()
+			}
+
+			return nil
+		})
+	}
+
+	g.Wait()
+	return errs
+}
@@ -17,6 +17,9 @@ type Synchronizer struct {
 	fix         bool
 	dropColumns bool
 	debug       bool
+	plan        bool
+	planResult  *Plan
+	parallelism int
 }
 
 func NewSynchronizer(config *Config) *Synchronizer {
@@ -31,6 +34,28 @@ func (sync *Synchronizer) SetDropColumns(dropColumns bool) {
 	sync.dropColumns = dropColumns
 }
 
+// shouldEmitDDL reports whether CheckTable should run (or, in plan mode,
+// record) a fix for detected drift.
+func (sync *Synchronizer) shouldEmitDDL() bool {
+	return sync.fix || sync.plan
+}
+
+// SetPlan switches the synchronizer into plan mode: CreateTable, CreateView,
+// ModifyColumn, AddColumn and DropColumn record the DDL they would have run
+// into Plan() instead of executing it.
+func (sync *Synchronizer) SetPlan(plan bool) {
+	sync.plan = plan
+	if plan {
+		sync.planResult = &Plan{}
+	}
+}
+
+// Plan returns the statements collected while running in plan mode, or nil
+// if plan mode was never enabled.
+func (sync *Synchronizer) Plan() *Plan {
+	return sync.planResult
+}
+
 func (sync *Synchronizer) SetupLogger(debug bool) {
 	sync.debug = debug
 	if debug {
@@ -167,28 +192,33 @@ func (e QueryResults) Close() {
 }
 
 func (sync *Synchronizer) Exec(query string, args ...interface{}) ExecResults {
-	var results ExecResults
+	results := make(ExecResults, len(sync.connections))
 
-	for _, conn := range sync.connections {
+	sync.forEachConnection(func(i int, conn *sql.DB) {
 		r, e := conn.Exec(query, args...)
-		results = append(results, ExecResult{r, e})
-	}
+		results[i] = ExecResult{r, e}
+	})
 
 	return results
 }
 
 func (sync *Synchronizer) Query(query string, args ...interface{}) QueryResults {
-	var results QueryResults
+	results := make(QueryResults, len(sync.connections))
 
-	for _, conn := range sync.connections {
+	sync.forEachConnection(func(i int, conn *sql.DB) {
 		r, e := conn.Query(query, args...)
-		results = append(results, QueryResult{r, e})
-	}
+		results[i] = QueryResult{r, e}
+	})
 
 	return results
 }
 
-func (sync *Synchronizer) CreateTable(i int, name string, table Table) {
+// CreateTable issues the CREATE TABLE for table on connection i. table.Engine
+// is passed through verbatim, so `{shard}`/`{replica}` macros in it (e.g.
+// `ReplicatedMergeTree('/clickhouse/tables/{shard}/name', '{replica}')`) are
+// left for the server to expand from its own <macros> config; chsync does not
+// substitute them itself.
+func (sync *Synchronizer) CreateTable(i int, name string, table Table) error {
 	conn := sync.connections[i]
 	l := log.WithFields(log.Fields{
 		"host":     sync.config.Servers[i].Host,
@@ -196,22 +226,22 @@ func (sync *Synchronizer) CreateTable(i int, name string, table Table) {
 		"table":    name,
 	})
 
-	createSQL := "CREATE TABLE IF NOT EXISTS " + name + " "
+	createSQL := "CREATE TABLE IF NOT EXISTS " + name + onClusterSQL(table.Cluster) + " "
 	if len(table.Columns) != 0 {
-		var typesSQL []string
-		for columnName, columnType := range table.Columns {
-			typesSQL = append(typesSQL, columnName+" "+columnType)
-		}
-
-		createSQL += "(" + strings.Join(typesSQL, ", ") + ") "
+		createSQL += columnsSQL(table.Columns)
 	} else if table.AsAnotherTable != "" {
 		createSQL += "AS " + table.AsAnotherTable + " "
 	}
 
-	createSQL += "ENGINE = " + table.Engine
+	createSQL += "ENGINE = " + table.Engine + tableClausesSQL(table)
 
 	l.Debug(createSQL)
 
+	if sync.plan {
+		sync.planResult.add(sync.config.Servers[i].Host, sync.database, name, "table does not exist", createSQL)
+		return nil
+	}
+
 	_, err := conn.Exec(createSQL)
 
 	if err != nil {
@@ -219,9 +249,14 @@ func (sync *Synchronizer) CreateTable(i int, name string, table Table) {
 	} else {
 		l.Info("Created table")
 	}
+
+	return err
 }
 
-func (sync *Synchronizer) CreateView(i int, name string, view Table) {
+// CreateView issues the CREATE [MATERIALIZED] VIEW for view on connection i.
+// As with CreateTable, view.Engine is passed through verbatim and any
+// `{shard}`/`{replica}` macros it contains are expanded server-side.
+func (sync *Synchronizer) CreateView(i int, name string, view Table) error {
 	conn := sync.connections[i]
 	l := log.WithFields(log.Fields{
 		"host":     sync.config.Servers[i].Host,
@@ -231,7 +266,7 @@ func (sync *Synchronizer) CreateView(i int, name string, view Table) {
 
 	if view.AsSelect == "" {
 		l.Error("as_select is not defined")
-		return
+		return errors.New("as_select is not defined")
 	}
 
 	var createSQL string
@@ -241,21 +276,16 @@ func (sync *Synchronizer) CreateView(i int, name string, view Table) {
 		createSQL = "CREATE VIEW IF NOT EXISTS "
 	}
 
-	createSQL += name + " "
+	createSQL += name + onClusterSQL(view.Cluster) + " "
 
 	if len(view.Columns) != 0 {
-		var typesSQL []string
-		for columnName, columnType := range view.Columns {
-			typesSQL = append(typesSQL, columnName+" "+columnType)
-		}
-
-		createSQL += "(" + strings.Join(typesSQL, ", ") + ") "
+		createSQL += columnsSQL(view.Columns)
 	} else if view.AsAnotherTable != "" {
 
 	}
 
 	if view.Engine != "" {
-		createSQL += "ENGINE = " + view.Engine + " "
+		createSQL += "ENGINE = " + view.Engine + tableClausesSQL(view) + " "
 	}
 
 	if view.Populate {
@@ -266,6 +296,11 @@ func (sync *Synchronizer) CreateView(i int, name string, view Table) {
 
 	l.Debug(createSQL)
 
+	if sync.plan {
+		sync.planResult.add(sync.config.Servers[i].Host, sync.database, name, "view does not exist", createSQL)
+		return nil
+	}
+
 	_, err := conn.Exec(createSQL)
 
 	if err != nil {
@@ -273,22 +308,29 @@ func (sync *Synchronizer) CreateView(i int, name string, view Table) {
 	} else {
 		l.Info("Created view")
 	}
+
+	return err
 }
 
-func (sync *Synchronizer) ModifyColumn(i int, name, columnName, columnType string) {
+func (sync *Synchronizer) ModifyColumn(i int, name, columnName string, column Column, cluster string) error {
 	conn := sync.connections[i]
 	l := log.WithFields(log.Fields{
 		"host":     sync.config.Servers[i].Host,
 		"database": sync.database,
 		"view":     name,
 		"column":   columnName,
-		"type":     columnType,
+		"type":     column.Type,
 	})
 
-	modifySQL := "ALTER TABLE " + name + " MODIFY COLUMN " + columnName + " " + columnType
+	modifySQL := "ALTER TABLE " + name + onClusterSQL(cluster) + " MODIFY COLUMN " + columnSQL(columnName, column)
 
 	l.Debug(modifySQL)
 
+	if sync.plan {
+		sync.planResult.add(sync.config.Servers[i].Host, sync.database, name, "column type mismatch", modifySQL)
+		return nil
+	}
+
 	_, err := conn.Exec(modifySQL)
 
 	if err != nil {
@@ -296,22 +338,29 @@ func (sync *Synchronizer) ModifyColumn(i int, name, columnName, columnType strin
 	} else {
 		l.Info("Modified column type")
 	}
+
+	return err
 }
 
-func (sync *Synchronizer) AddColumn(i int, name, columnName, columnType string) {
+func (sync *Synchronizer) AddColumn(i int, name, columnName string, column Column, cluster string) error {
 	conn := sync.connections[i]
 	l := log.WithFields(log.Fields{
 		"host":     sync.config.Servers[i].Host,
 		"database": sync.database,
 		"view":     name,
 		"column":   columnName,
-		"type":     columnType,
+		"type":     column.Type,
 	})
 
-	modifySQL := "ALTER TABLE " + name + " ADD COLUMN " + columnName + " " + columnType
+	modifySQL := "ALTER TABLE " + name + onClusterSQL(cluster) + " ADD COLUMN " + columnSQL(columnName, column)
 
 	l.Debug(modifySQL)
 
+	if sync.plan {
+		sync.planResult.add(sync.config.Servers[i].Host, sync.database, name, "missing column", modifySQL)
+		return nil
+	}
+
 	_, err := conn.Exec(modifySQL)
 
 	if err != nil {
@@ -319,9 +368,11 @@ func (sync *Synchronizer) AddColumn(i int, name, columnName, columnType string)
 	} else {
 		l.Info("Added column")
 	}
+
+	return err
 }
 
-func (sync *Synchronizer) DropColumn(i int, name, columnName string) {
+func (sync *Synchronizer) DropColumn(i int, name, columnName, cluster string) error {
 	conn := sync.connections[i]
 	l := log.WithFields(log.Fields{
 		"host":     sync.config.Servers[i].Host,
@@ -330,10 +381,15 @@ func (sync *Synchronizer) DropColumn(i int, name, columnName string) {
 		"column":   columnName,
 	})
 
-	modifySQL := "ALTER TABLE " + name + " DROP COLUMN " + columnName
+	modifySQL := "ALTER TABLE " + name + onClusterSQL(cluster) + " DROP COLUMN " + columnName
 
 	l.Debug(modifySQL)
 
+	if sync.plan {
+		sync.planResult.add(sync.config.Servers[i].Host, sync.database, name, "excess column", modifySQL)
+		return nil
+	}
+
 	_, err := conn.Exec(modifySQL)
 
 	if err != nil {
@@ -341,21 +397,80 @@ func (sync *Synchronizer) DropColumn(i int, name, columnName string) {
 	} else {
 		l.Info("Dropped column")
 	}
+
+	return err
 }
 
-func (sync *Synchronizer) CheckTable(name string, table Table) {
+func (sync *Synchronizer) ModifyColumnComment(i int, name, columnName, comment, cluster string) error {
+	conn := sync.connections[i]
+	l := log.WithFields(log.Fields{
+		"host":     sync.config.Servers[i].Host,
+		"database": sync.database,
+		"view":     name,
+		"column":   columnName,
+		"comment":  comment,
+	})
+
+	modifySQL := "ALTER TABLE " + name + onClusterSQL(cluster) + " COMMENT COLUMN " + columnName + " '" + escapeSQLString(comment) + "'"
+
+	l.Debug(modifySQL)
+
+	if sync.plan {
+		sync.planResult.add(sync.config.Servers[i].Host, sync.database, name, "column comment mismatch", modifySQL)
+		return nil
+	}
+
+	_, err := conn.Exec(modifySQL)
+
+	if err != nil {
+		l.WithError(err).Error("Failed to modify column comment")
+	} else {
+		l.Info("Modified column comment")
+	}
+
+	return err
+}
+
+func (sync *Synchronizer) CheckTable(name string, table Table) HostErrors {
 	l := log.WithFields(log.Fields{
 		"database": sync.database,
 		"table":    name,
 	})
 
-	r := sync.Query("SELECT name, type FROM system.columns WHERE database = ? AND table = ?", sync.database, name)
+	r := sync.Query("SELECT name, type, comment FROM system.columns WHERE database = ? AND table = ?", sync.database, name)
 	if r.HasError() {
 		l.Error(r.Error())
 	}
 	defer r.Close()
 
+	var representative map[int]bool
+	if table.Cluster != "" {
+		hosts := sync.representativeHosts(table.Cluster)
+		if len(hosts) == 0 {
+			l.WithField("cluster", table.Cluster).Error("Cluster resolves to no representative hosts, skipping table")
+			return HostErrors{{
+				Host: table.Cluster,
+				Op:   "resolve cluster",
+				Err:  fmt.Errorf("cluster %q is not configured or has no shards matching any configured server", table.Cluster),
+			}}
+		}
+
+		representative = make(map[int]bool, len(hosts))
+		for _, h := range hosts {
+			representative[h] = true
+			sync.validateClusterTopology(h, table.Cluster)
+		}
+	}
+
+	var tasks []fixTask
+
 	for i, e := range r {
+		i := i
+
+		if representative != nil && !representative[i] {
+			continue
+		}
+
 		l = l.WithField("host", sync.config.Servers[i].Host)
 
 		exists := false
@@ -364,35 +479,57 @@ func (sync *Synchronizer) CheckTable(name string, table Table) {
 		for e.Rows != nil && e.Rows.Next() {
 			exists = true
 
-			var columnName, columnType, needType string
-			var ok bool
+			var columnName, columnType, comment string
 
-			e.Rows.Scan(&columnName, &columnType)
+			e.Rows.Scan(&columnName, &columnType, &comment)
 			existColumns[columnName] = true
 
 			if len(table.Columns) == 0 {
 				continue
 			}
 
-			if needType, ok = table.Columns[columnName]; !ok {
+			column, ok := table.Columns[columnName]
+			if !ok {
 				l.WithField("column", columnName).Error("Table has excess column")
 
-				if sync.fix && sync.dropColumns {
-					go sync.DropColumn(i, name, columnName)
+				if sync.shouldEmitDDL() && sync.dropColumns {
+					tasks = append(tasks, fixTask{host: i, op: "drop column " + columnName, run: func() error {
+						return sync.DropColumn(i, name, columnName, table.Cluster)
+					}})
 				}
 				continue
 			}
 
-			needType = strings.Fields(needType)[0]
-			if needType != columnType {
+			needType := strings.Join(strings.Fields(column.Type), " ")
+			if needType == "" {
+				l.WithField("column", columnName).Warn("Column has no type configured, skipping type check")
+			} else if hasType := strings.Join(strings.Fields(columnType), " "); needType != hasType {
 				l.WithFields(log.Fields{
 					"column":    columnName,
 					"need type": needType,
 					"has type":  columnType,
 				}).Error("Column type mismatch")
 
-				if sync.fix {
-					go sync.ModifyColumn(i, name, columnName, needType)
+				if sync.shouldEmitDDL() {
+					column := column
+					tasks = append(tasks, fixTask{host: i, op: "modify column " + columnName, run: func() error {
+						return sync.ModifyColumn(i, name, columnName, column, table.Cluster)
+					}})
+				}
+			}
+
+			if column.Comment != "" && column.Comment != comment {
+				l.WithFields(log.Fields{
+					"column":       columnName,
+					"need comment": column.Comment,
+					"has comment":  comment,
+				}).Error("Column comment mismatch")
+
+				if sync.shouldEmitDDL() {
+					column := column
+					tasks = append(tasks, fixTask{host: i, op: "comment column " + columnName, run: func() error {
+						return sync.ModifyColumnComment(i, name, columnName, column.Comment, table.Cluster)
+					}})
 				}
 			}
 		}
@@ -400,32 +537,76 @@ func (sync *Synchronizer) CheckTable(name string, table Table) {
 		if !exists {
 			l.Error("Table does not exist")
 
-			if sync.fix {
+			if sync.shouldEmitDDL() {
 				if table.View {
-					go sync.CreateView(i, name, table)
+					tasks = append(tasks, fixTask{host: i, op: "create view", run: func() error {
+						return sync.CreateView(i, name, table)
+					}})
 				} else {
-					go sync.CreateTable(i, name, table)
+					tasks = append(tasks, fixTask{host: i, op: "create table", run: func() error {
+						return sync.CreateTable(i, name, table)
+					}})
 				}
 			}
 
 			continue
 		}
 
-		for columnName, needType := range table.Columns {
+		for columnName, column := range table.Columns {
 			if _, exists := existColumns[columnName]; exists {
 				continue
 			}
 
 			l.WithField("column", columnName).Error("Table has not enough columns")
 
-			if sync.fix {
-				go sync.AddColumn(i, name, columnName, needType)
+			if sync.shouldEmitDDL() {
+				columnName, column := columnName, column
+				tasks = append(tasks, fixTask{host: i, op: "add column " + columnName, run: func() error {
+					return sync.AddColumn(i, name, columnName, column, table.Cluster)
+				}})
 			}
 		}
+
+		sync.checkTableStructure(i, name, table, l)
+	}
+
+	return sync.runFixTasks(tasks)
+}
+
+// checkTableStructure detects drift in clauses that ClickHouse does not
+// support altering after creation (ORDER BY, PARTITION BY), logging a
+// mismatch instead of attempting a fix.
+func (sync *Synchronizer) checkTableStructure(i int, name string, table Table, l *log.Entry) {
+	if len(table.OrderBy) == 0 && table.PartitionBy == "" {
+		return
+	}
+
+	var sortingKey, partitionKey string
+	row := sync.connections[i].QueryRow(
+		"SELECT sorting_key, partition_key FROM system.tables WHERE database = ? AND name = ?",
+		sync.database, name,
+	)
+	if err := row.Scan(&sortingKey, &partitionKey); err != nil {
+		l.WithError(err).Error("Failed to read table structure")
+		return
+	}
+
+	if len(table.OrderBy) != 0 && sortingKey != strings.Join(table.OrderBy, ", ") {
+		l.WithFields(log.Fields{
+			"need order by": strings.Join(table.OrderBy, ", "),
+			"has order by":  sortingKey,
+		}).Error("Table ORDER BY mismatch")
+	}
+
+	if table.PartitionBy != "" && partitionKey != table.PartitionBy {
+		l.WithFields(log.Fields{
+			"need partition by": table.PartitionBy,
+			"has partition by":  partitionKey,
+		}).Error("Table PARTITION BY mismatch")
 	}
 }
 
-func (sync *Synchronizer) CheckDatabase(db Database) {
+func (sync *Synchronizer) CheckDatabase(db Database) HostErrors {
 	r := sync.Exec(fmt.Sprintf("USE %s", db.Name))
 	if r.HasError() {
 		panic(r)
@@ -433,13 +614,24 @@ func (sync *Synchronizer) CheckDatabase(db Database) {
 
 	sync.database = db.Name
 
+	var errs HostErrors
 	for name, table := range db.Tables {
-		sync.CheckTable(name, table)
+		if table.Cluster == "" {
+			table.Cluster = db.Cluster
+		}
+
+		errs = append(errs, sync.CheckTable(name, table)...)
+		errs = append(errs, sync.checkDistributedTable(name, table)...)
 	}
+
+	return errs
 }
 
-func (sync *Synchronizer) Check() {
+func (sync *Synchronizer) Check() HostErrors {
+	var errs HostErrors
 	for _, db := range sync.config.Databases {
-		sync.CheckDatabase(db)
+		errs = append(errs, sync.CheckDatabase(db)...)
 	}
+
+	return errs
 }